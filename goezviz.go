@@ -1,6 +1,7 @@
 package ezviz
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,7 +11,12 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -21,17 +27,63 @@ const (
 
 //DingTalkClient is the Client to access DingTalk Open API
 type EzvizClient struct {
-	AppKey      string
-	AppSecret   string
+	AppKey    string
+	AppSecret string
+
+	//AccessToken is the current access token. It's read and written
+	//internally under tokenMu; prefer letting RefreshAccessToken manage
+	//it rather than assigning it directly from another goroutine.
 	AccessToken string
 	HTTPClient  *http.Client
 	Cache       Cache
+	TokenIssuer TokenIssuer
+
+	//MaxRetries is how many times a request is retried after a transient
+	//network error or a retryable Ezviz error code (e.g. the 49999 QPS
+	//quota error). 0 disables retries.
+	MaxRetries int
+	//BackoffPolicy controls the delay between retries. Defaults to an
+	//ExponentialBackoff with jitter.
+	BackoffPolicy BackoffPolicy
+	//RateLimit gates outbound calls to stay within Ezviz's per-appKey QPS
+	//quota. nil disables rate limiting.
+	RateLimit *rate.Limiter
+
+	//Logger receives structured events for request start, response,
+	//retry, token refresh and cache hit/miss. Defaults to NopLogger; set
+	//NewStdLogger, or an adapter from ezviz/zaplog or ezviz/zerologlog,
+	//to see them.
+	Logger Logger
+
+	//Tracer, if set, wraps every outbound request in an OpenTelemetry
+	//span so Ezviz calls show up alongside a caller's other HTTP
+	//dependencies.
+	Tracer trace.Tracer
+
+	tokenMu      sync.RWMutex
+	refreshGroup tokenRefreshGroup
+}
+
+//accessToken returns the current access token, synchronized against
+//concurrent refreshes triggered by other in-flight requests.
+func (c *EzvizClient) accessToken() string {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return c.AccessToken
+}
+
+//setAccessTokenValue stores a newly issued access token
+func (c *EzvizClient) setAccessTokenValue(token string) {
+	c.tokenMu.Lock()
+	c.AccessToken = token
+	c.tokenMu.Unlock()
 }
 
 //Unmarshallable is
 type Unmarshallable interface {
 	checkError() error
 	getWriter() io.Writer
+	getCode() string
 }
 
 //OAPIResponse is
@@ -51,6 +103,10 @@ func (data *OAPIResponse) getWriter() io.Writer {
 	return nil
 }
 
+func (data *OAPIResponse) getCode() string {
+	return data.Code
+}
+
 type AccessToken struct {
 	AccessToken string `json:"accessToken"`
 	ExpireTime  int64  `json:"expireTime"`
@@ -76,55 +132,205 @@ func NewEzvizClient(appKey string, appSecret string) *EzvizClient {
 		Timeout: 10 * time.Second,
 	}
 	c.Cache = NewFileCache(fmt.Sprintf("ezviz_%s.auth_file", appKey))
+	c.TokenIssuer = &ClientCredentialsIssuer{}
+	c.MaxRetries = 3
+	c.BackoffPolicy = DefaultBackoffPolicy()
+	c.RateLimit = rate.NewLimiter(rate.Limit(5), 5)
+	c.Logger = NopLogger{}
 	return c
 }
 
-//RefreshAccessToken is to get a valid access token
-func (c *EzvizClient) RefreshAccessToken() error {
+//RefreshAccessToken is to get a valid access token, reusing a cached one if
+//it hasn't expired yet
+func (c *EzvizClient) RefreshAccessToken(ctx context.Context) error {
 	var res AccessTokenResponse
 	err := c.Cache.Get(&res)
 	if err == nil {
-		c.AccessToken = res.Data.AccessToken
+		c.setAccessTokenValue(res.Data.AccessToken)
+		c.logger().Debug("token cache hit", F("appKey", c.AppKey))
 		return nil
 	}
+	c.logger().Debug("token cache miss", F("appKey", c.AppKey))
+	return c.forceRefreshAccessToken(ctx, "")
+}
 
-	params := url.Values{}
-	params["appKey"] = []string{c.AppKey}
-	params["appSecret"] = []string{c.AppSecret}
-	err = c.httpRPC("/lapp/token/get", params, nil, &res)
-	if err == nil {
-		c.AccessToken = res.Data.AccessToken
-		err = c.Cache.Set(&res)
+//logger returns c.Logger, falling back to NopLogger for zero-value clients
+func (c *EzvizClient) logger() Logger {
+	if c.Logger == nil {
+		return NopLogger{}
+	}
+	return c.Logger
+}
+
+//forceRefreshAccessToken re-issues a token via the TokenIssuer. A cached
+//token is reused instead only if it differs from staleToken, the token that
+//was just rejected. Concurrent callers within this process are coalesced
+//onto a single in-flight issuance, and a Cache.Lock/Unlock pair guards
+//against every process in a cluster doing the same thing at once.
+func (c *EzvizClient) forceRefreshAccessToken(ctx context.Context, staleToken string) error {
+	return c.refreshGroup.do(func() error {
+		lockKey := c.tokenLockKey()
+		if err := c.Cache.Lock(lockKey); err != nil {
+			return err
+		}
+		defer c.Cache.Unlock(lockKey)
+
+		// Another process may have refreshed the token while we were
+		// waiting for the lock; check the cache once more before
+		// hitting the issuer.
+		var cached AccessTokenResponse
+		if err := c.Cache.Get(&cached); err == nil && cached.Data.AccessToken != staleToken {
+			c.setAccessTokenValue(cached.Data.AccessToken)
+			return nil
+		}
+
+		c.logger().Info("refreshing access token", F("appKey", c.AppKey))
+		res, err := c.TokenIssuer.IssueToken(ctx, c)
+		if err != nil {
+			c.logger().Error("token refresh failed", F("appKey", c.AppKey), F("error", err))
+			return err
+		}
+		c.setAccessTokenValue(res.Data.AccessToken)
+		return c.Cache.Set(res)
+	})
+}
+
+func (c *EzvizClient) tokenLockKey() string {
+	return fmt.Sprintf("ezviz_%s.token_lock", c.AppKey)
+}
+
+//isTokenError reports whether code is one of Ezviz's "token invalid/expired"
+//error codes, which warrant a transparent re-authentication.
+func isTokenError(code string) bool {
+	switch code {
+	case "10002", "10004":
+		return true
+	}
+	return false
+}
+
+func (c *EzvizClient) httpRPC(ctx context.Context, path string, params url.Values, requestData interface{}, responseData Unmarshallable) error {
+	if params == nil {
+		params = url.Values{}
+	}
+	c.applyAccessToken(params)
+	err := c.httpRequest(ctx, path, params, requestData, responseData)
+	if err != nil && isTokenError(responseData.getCode()) {
+		if refreshErr := c.forceRefreshAccessToken(ctx, params.Get("accessToken")); refreshErr != nil {
+			return err
+		}
+		c.applyAccessToken(params)
+		err = c.httpRequest(ctx, path, params, requestData, responseData)
 	}
 	return err
 }
 
-func (c *EzvizClient) httpRPC(path string, params url.Values, requestData interface{}, responseData Unmarshallable) error {
-	if c.AccessToken != "" {
-		if params == nil {
-			params = url.Values{}
+//applyAccessToken copies the current access token into params, synchronized
+//against concurrent refreshes.
+func (c *EzvizClient) applyAccessToken(params url.Values) {
+	if token := c.accessToken(); token != "" {
+		params.Set("accessToken", token)
+	}
+}
+
+//httpRequest issues the request, retrying on network errors and retryable
+//Ezviz error codes (e.g. the 49999 QPS quota error) per MaxRetries/
+//BackoffPolicy, and gating every attempt through RateLimit.
+func (c *EzvizClient) httpRequest(ctx context.Context, path string, params url.Values, requestData interface{}, responseData Unmarshallable) error {
+	maxAttempts := c.MaxRetries + 1
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var err error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			c.logger().Warn("retrying request", F("path", path), F("attempt", attempt), F("error", err))
+			if waitErr := c.waitBackoff(ctx, attempt-1); waitErr != nil {
+				return waitErr
+			}
+		}
+
+		if c.RateLimit != nil {
+			if waitErr := c.RateLimit.Wait(ctx); waitErr != nil {
+				return waitErr
+			}
+		}
+
+		c.logger().Debug("request start", F("path", path), F("attempt", attempt))
+		err = c.doHTTPRequest(ctx, path, params, responseData)
+		if err != nil {
+			c.logger().Error("response", F("path", path), F("attempt", attempt), F("error", err))
+		} else {
+			c.logger().Debug("response", F("path", path), F("attempt", attempt), F("code", responseData.getCode()))
 		}
-		if params.Get("accessToken") == "" {
-			params.Set("accessToken", c.AccessToken)
+		if err == nil || !c.isRetryable(err, responseData) {
+			return err
 		}
 	}
-	return c.httpRequest(path, params, requestData, responseData)
+	return err
+}
+
+func (c *EzvizClient) waitBackoff(ctx context.Context, attempt int) error {
+	policy := c.BackoffPolicy
+	if policy == nil {
+		policy = DefaultBackoffPolicy()
+	}
+	timer := time.NewTimer(policy.NextBackoff(attempt))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+//isRetryable reports whether err is worth retrying: a transport-level error,
+//or an Ezviz response carrying a retryable error code.
+func (c *EzvizClient) isRetryable(err error, responseData Unmarshallable) bool {
+	if _, ok := err.(*oapiError); ok {
+		return isRetryableCode(responseData.getCode())
+	}
+	return true
+}
+
+//oapiError marks an error as coming from responseData.checkError(), as
+//opposed to a transport-level failure.
+type oapiError struct {
+	err error
+}
+
+func (e *oapiError) Error() string {
+	return e.err.Error()
 }
 
-func (c *EzvizClient) httpRequest(path string, params url.Values, requestData interface{}, responseData Unmarshallable) error {
+func (c *EzvizClient) doHTTPRequest(ctx context.Context, path string, params url.Values, responseData Unmarshallable) (err error) {
+	if c.Tracer != nil {
+		var span trace.Span
+		ctx, span = c.Tracer.Start(ctx, "ezviz."+path, trace.WithAttributes(attribute.String("ezviz.path", path)))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+			}
+			span.End()
+		}()
+	}
+
 	client := c.HTTPClient
-	var request *http.Request
 	ROOT := os.Getenv("oapi_server")
 	if ROOT == "" {
 		ROOT = "open.ys7.com/api"
 	}
 	DEBUG := os.Getenv("debug") != ""
 	url2 := "https://" + ROOT + "/" + path + "?" + params.Encode()
-	// log.Println(url2)
 	if DEBUG {
 		log.Printf("url: %s", url2)
 	}
-	request, _ = http.NewRequest("POST", url2, nil)
+	request, err := http.NewRequestWithContext(ctx, "POST", url2, nil)
+	if err != nil {
+		return err
+	}
 	resp, err := client.Do(request)
 	if err != nil {
 		return err
@@ -145,13 +351,19 @@ func (c *EzvizClient) httpRequest(path string, params url.Values, requestData in
 		if DEBUG {
 			log.Println(string(content))
 		}
-		if err == nil {
-			json.Unmarshal(content, responseData)
-			return responseData.checkError()
+		if err != nil {
+			return err
 		}
-	} else {
-		io.Copy(responseData.getWriter(), resp.Body)
-		return responseData.checkError()
+		json.Unmarshal(content, responseData)
+		if err := responseData.checkError(); err != nil {
+			return &oapiError{err: err}
+		}
+		return nil
 	}
-	return err
+
+	io.Copy(responseData.getWriter(), resp.Body)
+	if err := responseData.checkError(); err != nil {
+		return &oapiError{err: err}
+	}
+	return nil
 }
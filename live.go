@@ -0,0 +1,50 @@
+package ezviz
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+//LiveProtocol selects the streaming protocol returned by
+///lapp/v2/live/address/get
+type LiveProtocol int
+
+const (
+	//LiveProtocolEZOPEN is Ezviz's native EZOPEN protocol
+	LiveProtocolEZOPEN LiveProtocol = 1
+	//LiveProtocolHLS is HTTP Live Streaming
+	LiveProtocolHLS LiveProtocol = 2
+	//LiveProtocolRTMP is Real-Time Messaging Protocol
+	LiveProtocolRTMP LiveProtocol = 3
+)
+
+//LiveAddress is a live-view address for a single device channel
+type LiveAddress struct {
+	URL          string `json:"url"`
+	HLS          string `json:"hls"`
+	RTMP         string `json:"rtmp"`
+	DeviceSerial string `json:"deviceSerial"`
+	ChannelNo    int    `json:"channelNo"`
+}
+
+//LiveAddressResponse is
+type LiveAddressResponse struct {
+	OAPIResponse
+	Data LiveAddress `json:"data"`
+}
+
+//GetLiveAddress fetches the live-view address for a device channel in the
+//given protocol
+func (c *EzvizClient) GetLiveAddress(ctx context.Context, deviceSerial string, channelNo int, protocol LiveProtocol) (*LiveAddress, error) {
+	params := url.Values{}
+	params.Set("deviceSerial", deviceSerial)
+	params.Set("channelNo", strconv.Itoa(channelNo))
+	params.Set("protocol", strconv.Itoa(int(protocol)))
+	var res LiveAddressResponse
+	err := c.httpRPC(ctx, "/lapp/v2/live/address/get", params, nil, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Data, nil
+}
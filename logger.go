@@ -0,0 +1,73 @@
+package ezviz
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+//Field is a single structured key-value pair attached to a log event
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+//F builds a Field
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+//Logger is the structured logging interface EzvizClient emits request
+//lifecycle events through: request start, response, retry, token refresh and
+//cache hit/miss. Adapters for zap and zerolog live in the ezviz/zaplog and
+//ezviz/zerologlog subpackages so the core ezviz package doesn't import either
+//dependency; NewStdLogger wraps the standard library logger for callers who
+//don't need either.
+type Logger interface {
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+}
+
+//NopLogger discards every event. It's the default on EzvizClient.
+type NopLogger struct{}
+
+func (NopLogger) Debug(msg string, fields ...Field) {}
+func (NopLogger) Info(msg string, fields ...Field)  {}
+func (NopLogger) Warn(msg string, fields ...Field)  {}
+func (NopLogger) Error(msg string, fields ...Field) {}
+
+//StdLogger adapts the standard library *log.Logger to the Logger interface
+type StdLogger struct {
+	*log.Logger
+}
+
+//NewStdLogger wraps l, or a default logger writing to stderr if l is nil
+func NewStdLogger(l *log.Logger) *StdLogger {
+	if l == nil {
+		l = log.New(os.Stderr, "", log.LstdFlags)
+	}
+	return &StdLogger{Logger: l}
+}
+
+func (s *StdLogger) Debug(msg string, fields ...Field) { s.log("DEBUG", msg, fields) }
+func (s *StdLogger) Info(msg string, fields ...Field)  { s.log("INFO", msg, fields) }
+func (s *StdLogger) Warn(msg string, fields ...Field)  { s.log("WARN", msg, fields) }
+func (s *StdLogger) Error(msg string, fields ...Field) { s.log("ERROR", msg, fields) }
+
+func (s *StdLogger) log(level, msg string, fields []Field) {
+	s.Logger.Printf("[%s] %s%s", level, msg, formatFields(fields))
+}
+
+func formatFields(fields []Field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, f := range fields {
+		fmt.Fprintf(&b, " %s=%v", f.Key, f.Value)
+	}
+	return b.String()
+}
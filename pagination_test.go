@@ -0,0 +1,157 @@
+package ezviz
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+//newTestClient starts an HTTPS test server backed by handler and returns an
+//EzvizClient wired to talk to it via the oapi_server override doHTTPRequest
+//reads.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *EzvizClient {
+	t.Helper()
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+	t.Setenv("oapi_server", strings.TrimPrefix(server.URL, "https://"))
+
+	c := NewEzvizClient("key", "secret")
+	c.HTTPClient = server.Client()
+	return c
+}
+
+func TestDeviceIteratorStopsAtPageTotal(t *testing.T) {
+	pages := [][]Device{
+		{{DeviceSerial: "A"}, {DeviceSerial: "B"}},
+		{{DeviceSerial: "C"}, {DeviceSerial: "D"}},
+		{{DeviceSerial: "E"}},
+	}
+	const total = 5
+	var calls int
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		pageStart, _ := strconv.Atoi(r.URL.Query().Get("pageStart"))
+		if pageStart >= len(pages) {
+			t.Fatalf("unexpected pageStart %d", pageStart)
+		}
+		calls++
+		res := DeviceListResponse{Data: pages[pageStart], Page: Page{Total: total}}
+		res.Code = "200"
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	})
+
+	got := collectDevices(t, c.ListDevices(2))
+	want := []string{"A", "B", "C", "D", "E"}
+	assertSerials(t, got, want)
+	if calls != 3 {
+		t.Errorf("expected 3 page fetches, got %d", calls)
+	}
+}
+
+//TestDeviceIteratorSurvivesServerCappedPageSize reproduces the bug the
+//Page.Total based termination fixes: Ezviz caps pageSize server-side, so a
+//page shorter than the requested pageSize doesn't mean the list is
+//exhausted.
+func TestDeviceIteratorSurvivesServerCappedPageSize(t *testing.T) {
+	pages := [][]Device{
+		{{DeviceSerial: "A"}, {DeviceSerial: "B"}},
+		{{DeviceSerial: "C"}},
+	}
+	const total = 3
+	const requestedPageSize = 100
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		pageStart, _ := strconv.Atoi(r.URL.Query().Get("pageStart"))
+		if pageStart >= len(pages) {
+			t.Fatalf("unexpected pageStart %d", pageStart)
+		}
+		res := DeviceListResponse{Data: pages[pageStart], Page: Page{Total: total}}
+		res.Code = "200"
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	})
+
+	got := collectDevices(t, c.ListDevices(requestedPageSize))
+	assertSerials(t, got, []string{"A", "B", "C"})
+}
+
+func TestAlarmIteratorStopsAtPageTotal(t *testing.T) {
+	pages := [][]Alarm{
+		{{AlarmID: "1"}, {AlarmID: "2"}},
+		{{AlarmID: "3"}},
+	}
+	const total = 3
+	var calls int
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		pageStart, _ := strconv.Atoi(r.URL.Query().Get("pageStart"))
+		if pageStart >= len(pages) {
+			t.Fatalf("unexpected pageStart %d", pageStart)
+		}
+		calls++
+		res := AlarmListResponse{Data: pages[pageStart], Page: Page{Total: total}}
+		res.Code = "200"
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	})
+
+	it := c.ListAlarms(AlarmListOptions{}, 2)
+	var got []string
+	for {
+		a, err := it.Next(context.Background())
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		got = append(got, a.AlarmID)
+	}
+	want := []string{"1", "2", "3"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v, want %v", got, want)
+			break
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 page fetches, got %d", calls)
+	}
+}
+
+func collectDevices(t *testing.T, it *DeviceIterator) []string {
+	t.Helper()
+	var got []string
+	for {
+		d, err := it.Next(context.Background())
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		got = append(got, d.DeviceSerial)
+	}
+	return got
+}
+
+func assertSerials(t *testing.T, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package ezviz
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+//PTZDirection is a pan/tilt/zoom movement direction for /lapp/device/ptz/*
+type PTZDirection int
+
+const (
+	PTZDirectionUp      PTZDirection = 0
+	PTZDirectionDown    PTZDirection = 1
+	PTZDirectionLeft    PTZDirection = 2
+	PTZDirectionRight   PTZDirection = 3
+	PTZDirectionZoomIn  PTZDirection = 8
+	PTZDirectionZoomOut PTZDirection = 9
+)
+
+//PTZStart begins a PTZ movement on a device channel. speed ranges 0-2
+//(slow-fast); it is ignored by devices that don't support variable speed.
+func (c *EzvizClient) PTZStart(ctx context.Context, deviceSerial string, channelNo int, direction PTZDirection, speed int) error {
+	params := url.Values{}
+	params.Set("deviceSerial", deviceSerial)
+	params.Set("channelNo", strconv.Itoa(channelNo))
+	params.Set("direction", strconv.Itoa(int(direction)))
+	params.Set("speed", strconv.Itoa(speed))
+	var res OAPIResponse
+	return c.httpRPC(ctx, "/lapp/device/ptz/start", params, nil, &res)
+}
+
+//PTZStop ends a PTZ movement started with PTZStart
+func (c *EzvizClient) PTZStop(ctx context.Context, deviceSerial string, channelNo int, direction PTZDirection) error {
+	params := url.Values{}
+	params.Set("deviceSerial", deviceSerial)
+	params.Set("channelNo", strconv.Itoa(channelNo))
+	params.Set("direction", strconv.Itoa(int(direction)))
+	var res OAPIResponse
+	return c.httpRPC(ctx, "/lapp/device/ptz/stop", params, nil, &res)
+}
@@ -0,0 +1,129 @@
+package ezviz
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+//unlockScript releases a lock only if it still holds the token this
+//RedisCache acquired it with, so that a lock which expired and was
+//re-acquired by another holder isn't deleted out from under them.
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+else
+	return 0
+end
+`)
+
+//RedisCache is a Cache backed by Redis, suitable for multi-process
+//deployments where FileCache/InMemoryCache would race to refresh the token
+//and burn through the daily /lapp/token/get quota. It stores the
+//AccessTokenResponse JSON under Key with a TTL derived from ExpireTime, and
+//implements Lock/Unlock as a SETNX-based distributed lock.
+type RedisCache struct {
+	Client *redis.Client
+	Key    string
+
+	//LockTTL bounds how long a lock can be held before it's considered
+	//abandoned (e.g. the holder crashed mid-refresh). Defaults to 10s.
+	LockTTL time.Duration
+
+	//LockRetryInterval is how often Lock retries while waiting for a
+	//held lock to be released. Defaults to 100ms.
+	LockRetryInterval time.Duration
+
+	//LockWait bounds how long Lock waits for a contended lock before
+	//giving up. Defaults to 5s.
+	LockWait time.Duration
+
+	//lockTokens remembers the unique value each held lock was acquired
+	//with, so Unlock can release only the lock it actually owns.
+	lockTokens sync.Map
+}
+
+//NewRedisCache creates a RedisCache storing the token under key
+func NewRedisCache(client *redis.Client, key string) *RedisCache {
+	return &RedisCache{
+		Client:            client,
+		Key:               key,
+		LockTTL:           10 * time.Second,
+		LockRetryInterval: 100 * time.Millisecond,
+		LockWait:          5 * time.Second,
+	}
+}
+
+func (c *RedisCache) Set(data Expirable) error {
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(time.Unix(data.GetExpireTime()/1000, 0))
+	if ttl <= 0 {
+		return nil
+	}
+	return c.Client.Set(context.Background(), c.Key, bytes, ttl).Err()
+}
+
+func (c *RedisCache) Get(data Expirable) error {
+	bytes, err := c.Client.Get(context.Background(), c.Key).Bytes()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bytes, data)
+}
+
+func (c *RedisCache) Lock(key string) error {
+	ctx := context.Background()
+	lockKey := c.lockKey(key)
+	token, err := randomLockToken()
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(c.LockWait)
+	for {
+		ok, err := c.Client.SetNX(ctx, lockKey, token, c.LockTTL).Result()
+		if err != nil {
+			return err
+		}
+		if ok {
+			c.lockTokens.Store(lockKey, token)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.New("ezviz: timed out waiting for redis lock " + lockKey)
+		}
+		time.Sleep(c.LockRetryInterval)
+	}
+}
+
+//Unlock releases the lock only if it's still held with the token Lock
+//acquired it with; if the lock already expired and was taken over by
+//another holder, this is a no-op rather than deleting their lock.
+func (c *RedisCache) Unlock(key string) error {
+	lockKey := c.lockKey(key)
+	token, ok := c.lockTokens.LoadAndDelete(lockKey)
+	if !ok {
+		return nil
+	}
+	return unlockScript.Run(context.Background(), c.Client, []string{lockKey}, token).Err()
+}
+
+func (c *RedisCache) lockKey(key string) string {
+	return key + ".lock"
+}
+
+func randomLockToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
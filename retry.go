@@ -0,0 +1,52 @@
+package ezviz
+
+import (
+	"math/rand"
+	"time"
+)
+
+//BackoffPolicy computes how long to wait before the (attempt+1)th retry,
+//where attempt is 0 for the first retry.
+type BackoffPolicy interface {
+	NextBackoff(attempt int) time.Duration
+}
+
+//ExponentialBackoff is the default BackoffPolicy: the delay doubles with
+//each attempt up to MaxDelay, with up to 50% jitter so that many clients
+//retrying at once don't all hammer the API in lockstep.
+type ExponentialBackoff struct {
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+//DefaultBackoffPolicy returns the BackoffPolicy used by NewEzvizClient
+func DefaultBackoffPolicy() BackoffPolicy {
+	return &ExponentialBackoff{
+		BaseDelay: 200 * time.Millisecond,
+		MaxDelay:  5 * time.Second,
+	}
+}
+
+//NextBackoff implements BackoffPolicy
+func (b *ExponentialBackoff) NextBackoff(attempt int) time.Duration {
+	delay := b.BaseDelay
+	for i := 0; i < attempt; i++ {
+		delay *= 2
+		if delay >= b.MaxDelay {
+			delay = b.MaxDelay
+			break
+		}
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+//isRetryableCode reports whether code is a transient Ezviz error worth
+//retrying, such as the per-appKey QPS quota being hit.
+func isRetryableCode(code string) bool {
+	switch code {
+	case "49999", "20019":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package ezviz
+
+import (
+	"context"
+	"net/url"
+	"sync"
+)
+
+//TokenIssuer issues a fresh AccessTokenResponse for an EzvizClient. The
+//default implementation exchanges the client's appKey/appSecret, but callers
+//can plug in a refresh-token flow or a third-party auth provider by
+//implementing this interface and assigning it to EzvizClient.TokenIssuer.
+type TokenIssuer interface {
+	IssueToken(ctx context.Context, c *EzvizClient) (*AccessTokenResponse, error)
+}
+
+//ClientCredentialsIssuer is the default TokenIssuer: it exchanges the
+//client's appKey/appSecret for a token via /lapp/token/get.
+type ClientCredentialsIssuer struct{}
+
+//IssueToken implements TokenIssuer
+func (i *ClientCredentialsIssuer) IssueToken(ctx context.Context, c *EzvizClient) (*AccessTokenResponse, error) {
+	var res AccessTokenResponse
+	params := url.Values{}
+	params.Set("appKey", c.AppKey)
+	params.Set("appSecret", c.AppSecret)
+	err := c.httpRequest(ctx, "/lapp/token/get", params, nil, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+//tokenRefreshGroup coalesces concurrent token refreshes into a single
+//in-flight call, so that N goroutines racing on an expired token only hit
+///lapp/token/get once.
+type tokenRefreshGroup struct {
+	mu       sync.Mutex
+	inflight *tokenRefreshCall
+}
+
+type tokenRefreshCall struct {
+	wg  sync.WaitGroup
+	err error
+}
+
+func (g *tokenRefreshGroup) do(fn func() error) error {
+	g.mu.Lock()
+	if call := g.inflight; call != nil {
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.err
+	}
+
+	call := new(tokenRefreshCall)
+	call.wg.Add(1)
+	g.inflight = call
+	g.mu.Unlock()
+
+	call.err = fn()
+	call.wg.Done()
+
+	g.mu.Lock()
+	g.inflight = nil
+	g.mu.Unlock()
+
+	return call.err
+}
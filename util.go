@@ -1,7 +1,9 @@
 package ezviz
 
 import (
+	"crypto/hmac"
 	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -16,6 +18,12 @@ type Expirable interface {
 type Cache interface {
 	Set(data Expirable) error
 	Get(data Expirable) error
+
+	//Lock and Unlock guard a named distributed critical section, e.g. a
+	//token refresh, so that only one process at a time runs it. Single
+	//process implementations (FileCache, InMemoryCache) can no-op this.
+	Lock(key string) error
+	Unlock(key string) error
 }
 
 type FileCache struct {
@@ -50,6 +58,16 @@ func (c *FileCache) Get(data Expirable) error {
 	return err
 }
 
+//Lock is a no-op: FileCache only guards a single process
+func (c *FileCache) Lock(key string) error {
+	return nil
+}
+
+//Unlock is a no-op: FileCache only guards a single process
+func (c *FileCache) Unlock(key string) error {
+	return nil
+}
+
 type InMemoryCache struct {
 	data []byte
 }
@@ -77,7 +95,20 @@ func (c *InMemoryCache) Get(data Expirable) error {
 	return err
 }
 
-func sha1Sign(s string) string {
+//Lock is a no-op: InMemoryCache only guards a single process
+func (c *InMemoryCache) Lock(key string) error {
+	return nil
+}
+
+//Unlock is a no-op: InMemoryCache only guards a single process
+func (c *InMemoryCache) Unlock(key string) error {
+	return nil
+}
+
+//SHA1Sign returns the hex-encoded SHA1 digest of s. It implements Ezviz's
+//signing scheme and is exported so that subpackages (e.g. ezviz/webhook) can
+//reuse it without duplicating the hashing logic.
+func SHA1Sign(s string) string {
 	// The pattern for generating a hash is `sha1.New()`,
 	// `sha1.Write(bytes)`, then `sha1.Sum([]byte{})`.
 	// Here we start with a new hash.
@@ -97,3 +128,12 @@ func sha1Sign(s string) string {
 	// a hash results to a hex string.
 	return fmt.Sprintf("%x", bs)
 }
+
+//HMACSHA1Sign returns the hex-encoded HMAC-SHA1 of message keyed with key.
+//This is Ezviz's push-notification signing scheme, and is exported so that
+//subpackages (e.g. ezviz/webhook) can reuse it without duplicating it.
+func HMACSHA1Sign(key, message string) string {
+	mac := hmac.New(sha1.New, []byte(key))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
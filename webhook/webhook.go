@@ -0,0 +1,227 @@
+// Package webhook receives Ezviz's alarm and device-status push
+// notifications.
+//
+// Example wiring into an http.ServeMux:
+//
+//	h := webhook.NewHandler(appSecret)
+//	h.OnAlarm(func(e *webhook.AlarmEvent) {
+//		log.Printf("alarm on %s: %s", e.DeviceSerial, e.AlarmName)
+//	})
+//	h.OnDeviceOnline(func(e *webhook.DeviceStatusEvent) {
+//		log.Printf("device online: %s", e.DeviceSerial)
+//	})
+//
+//	mux := http.NewServeMux()
+//	mux.Handle("/ezviz/push", h)
+//	http.ListenAndServe(":8080", mux)
+package webhook
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/yellbuy/ezviz"
+)
+
+const (
+	eventTypeAlarm         = "alarm"
+	eventTypeDeviceOnline  = "deviceOnline"
+	eventTypeDeviceOffline = "deviceOffline"
+
+	signatureHeader = "X-Ca-Signature"
+)
+
+//AlarmEvent is the payload of an "alarm" push event
+type AlarmEvent struct {
+	DeviceSerial string `json:"deviceSerial"`
+	ChannelNo    int    `json:"channelNo"`
+	AlarmType    int    `json:"alarmType"`
+	AlarmName    string `json:"alarmName"`
+	AlarmTime    string `json:"alarmTime"`
+	PicURL       string `json:"picUrl"`
+}
+
+//DeviceStatusEvent is the payload of a "deviceOnline"/"deviceOffline" push
+//event
+type DeviceStatusEvent struct {
+	DeviceSerial string `json:"deviceSerial"`
+	Status       int    `json:"status"`
+	StatusTime   string `json:"statusTime"`
+}
+
+//pushEnvelope wraps every push notification; Data is decoded based on Type.
+//Timestamp is milliseconds since the Unix epoch, matching Ezviz's push
+//format.
+type pushEnvelope struct {
+	Type      string          `json:"type"`
+	Timestamp int64           `json:"timestamp"`
+	Nonce     string          `json:"nonce"`
+	Data      json.RawMessage `json:"data"`
+}
+
+//Handler is an http.Handler that verifies and dispatches Ezviz push
+//notifications to registered callbacks.
+type Handler struct {
+	//AppSecret is the same AppSecret configured on the ezviz.EzvizClient;
+	//it is used to verify the push signature.
+	AppSecret string
+
+	//ReplayWindow bounds how far a push's timestamp may drift from the
+	//current time before it's rejected as stale. Defaults to 5 minutes.
+	ReplayWindow time.Duration
+
+	mu              sync.Mutex
+	nonces          map[string]time.Time
+	onAlarm         []func(*AlarmEvent)
+	onDeviceOnline  []func(*DeviceStatusEvent)
+	onDeviceOffline []func(*DeviceStatusEvent)
+}
+
+//NewHandler creates a Handler that verifies pushes against appSecret
+func NewHandler(appSecret string) *Handler {
+	return &Handler{
+		AppSecret:    appSecret,
+		ReplayWindow: 5 * time.Minute,
+		nonces:       make(map[string]time.Time),
+	}
+}
+
+//OnAlarm registers fn to be called for every received alarm event
+func (h *Handler) OnAlarm(fn func(*AlarmEvent)) {
+	h.mu.Lock()
+	h.onAlarm = append(h.onAlarm, fn)
+	h.mu.Unlock()
+}
+
+//OnDeviceOnline registers fn to be called when a device comes online
+func (h *Handler) OnDeviceOnline(fn func(*DeviceStatusEvent)) {
+	h.mu.Lock()
+	h.onDeviceOnline = append(h.onDeviceOnline, fn)
+	h.mu.Unlock()
+}
+
+//OnDeviceOffline registers fn to be called when a device goes offline
+func (h *Handler) OnDeviceOffline(fn func(*DeviceStatusEvent)) {
+	h.mu.Lock()
+	h.onDeviceOffline = append(h.onDeviceOffline, fn)
+	h.mu.Unlock()
+}
+
+//ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "cannot read body", http.StatusBadRequest)
+		return
+	}
+
+	var env pushEnvelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		http.Error(w, "malformed payload", http.StatusBadRequest)
+		return
+	}
+
+	if !h.verifySignature(r, body) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	if !h.checkReplay(env.Timestamp, env.Nonce) {
+		http.Error(w, "stale or replayed request", http.StatusBadRequest)
+		return
+	}
+
+	switch env.Type {
+	case eventTypeAlarm:
+		var e AlarmEvent
+		if json.Unmarshal(env.Data, &e) == nil {
+			h.dispatchAlarm(&e)
+		}
+	case eventTypeDeviceOnline:
+		var e DeviceStatusEvent
+		if json.Unmarshal(env.Data, &e) == nil {
+			h.dispatchDeviceOnline(&e)
+		}
+	case eventTypeDeviceOffline:
+		var e DeviceStatusEvent
+		if json.Unmarshal(env.Data, &e) == nil {
+			h.dispatchDeviceOffline(&e)
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+//verifySignature recomputes the push signature the same way Ezviz signs
+//push notifications (HMAC-SHA1 of the raw body, keyed with AppSecret, via
+//ezviz.HMACSHA1Sign) and compares it in constant time.
+func (h *Handler) verifySignature(r *http.Request, body []byte) bool {
+	sig := r.Header.Get(signatureHeader)
+	if sig == "" {
+		return false
+	}
+	expected := ezviz.HMACSHA1Sign(h.AppSecret, string(body))
+	return hmac.Equal([]byte(sig), []byte(expected))
+}
+
+//checkReplay rejects timestamps outside ReplayWindow and nonces already
+//seen within it. timestamp is milliseconds since the Unix epoch, per
+//pushEnvelope.
+func (h *Handler) checkReplay(timestamp int64, nonce string) bool {
+	if nonce == "" {
+		return false
+	}
+	sentAt := time.UnixMilli(timestamp)
+	if time.Since(sentAt) > h.ReplayWindow || time.Until(sentAt) > h.ReplayWindow {
+		return false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.evictExpiredNonces()
+	if _, seen := h.nonces[nonce]; seen {
+		return false
+	}
+	h.nonces[nonce] = time.Now()
+	return true
+}
+
+func (h *Handler) evictExpiredNonces() {
+	cutoff := time.Now().Add(-h.ReplayWindow)
+	for nonce, seenAt := range h.nonces {
+		if seenAt.Before(cutoff) {
+			delete(h.nonces, nonce)
+		}
+	}
+}
+
+func (h *Handler) dispatchAlarm(e *AlarmEvent) {
+	h.mu.Lock()
+	fns := append([]func(*AlarmEvent){}, h.onAlarm...)
+	h.mu.Unlock()
+	for _, fn := range fns {
+		fn(e)
+	}
+}
+
+func (h *Handler) dispatchDeviceOnline(e *DeviceStatusEvent) {
+	h.mu.Lock()
+	fns := append([]func(*DeviceStatusEvent){}, h.onDeviceOnline...)
+	h.mu.Unlock()
+	for _, fn := range fns {
+		fn(e)
+	}
+}
+
+func (h *Handler) dispatchDeviceOffline(e *DeviceStatusEvent) {
+	h.mu.Lock()
+	fns := append([]func(*DeviceStatusEvent){}, h.onDeviceOffline...)
+	h.mu.Unlock()
+	for _, fn := range fns {
+		fn(e)
+	}
+}
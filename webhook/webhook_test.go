@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yellbuy/ezviz"
+)
+
+func TestCheckReplay(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		timestamp time.Time
+		nonce     string
+		seed      map[string]time.Time
+		want      bool
+	}{
+		{"valid", now, "n1", nil, true},
+		{"empty nonce", now, "", nil, false},
+		{"too far in the past", now.Add(-10 * time.Minute), "n1", nil, false},
+		{"too far in the future", now.Add(10 * time.Minute), "n1", nil, false},
+		{"duplicate nonce", now, "n1", map[string]time.Time{"n1": now}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler("secret")
+			for nonce, seenAt := range tt.seed {
+				h.nonces[nonce] = seenAt
+			}
+			got := h.checkReplay(tt.timestamp.UnixMilli(), tt.nonce)
+			if got != tt.want {
+				t.Errorf("checkReplay(%v, %q) = %v, want %v", tt.timestamp, tt.nonce, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckReplayRecordsNonce(t *testing.T) {
+	h := NewHandler("secret")
+	if !h.checkReplay(time.Now().UnixMilli(), "n1") {
+		t.Fatal("first use of nonce should be accepted")
+	}
+	if h.checkReplay(time.Now().UnixMilli(), "n1") {
+		t.Fatal("second use of the same nonce should be rejected as a replay")
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	const appSecret = "super-secret"
+	body := []byte(`{"type":"alarm"}`)
+	validSig := ezviz.HMACSHA1Sign(appSecret, string(body))
+
+	tests := []struct {
+		name string
+		sig  string
+		want bool
+	}{
+		{"valid signature", validSig, true},
+		{"missing signature", "", false},
+		{"wrong signature", "deadbeef", false},
+		{"plain SHA1 concat, not HMAC", ezviz.SHA1Sign(string(body) + appSecret), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(appSecret)
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.sig != "" {
+				r.Header.Set(signatureHeader, tt.sig)
+			}
+			if got := h.verifySignature(r, body); got != tt.want {
+				t.Errorf("verifySignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+// Package zaplog adapts a *zap.SugaredLogger to the ezviz.Logger interface.
+package zaplog
+
+import (
+	"github.com/yellbuy/ezviz"
+	"go.uber.org/zap"
+)
+
+//Logger adapts a *zap.SugaredLogger to ezviz.Logger
+type Logger struct {
+	Sugar *zap.SugaredLogger
+}
+
+//New wraps l
+func New(l *zap.SugaredLogger) *Logger {
+	return &Logger{Sugar: l}
+}
+
+func (l *Logger) Debug(msg string, fields ...ezviz.Field) {
+	l.Sugar.Debugw(msg, toArgs(fields)...)
+}
+
+func (l *Logger) Info(msg string, fields ...ezviz.Field) {
+	l.Sugar.Infow(msg, toArgs(fields)...)
+}
+
+func (l *Logger) Warn(msg string, fields ...ezviz.Field) {
+	l.Sugar.Warnw(msg, toArgs(fields)...)
+}
+
+func (l *Logger) Error(msg string, fields ...ezviz.Field) {
+	l.Sugar.Errorw(msg, toArgs(fields)...)
+}
+
+func toArgs(fields []ezviz.Field) []interface{} {
+	args := make([]interface{}, 0, len(fields)*2)
+	for _, f := range fields {
+		args = append(args, f.Key, f.Value)
+	}
+	return args
+}
@@ -0,0 +1,40 @@
+// Package zerologlog adapts a zerolog.Logger to the ezviz.Logger interface.
+package zerologlog
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/yellbuy/ezviz"
+)
+
+//Logger adapts a zerolog.Logger to ezviz.Logger
+type Logger struct {
+	Zerolog zerolog.Logger
+}
+
+//New wraps l
+func New(l zerolog.Logger) *Logger {
+	return &Logger{Zerolog: l}
+}
+
+func (l *Logger) Debug(msg string, fields ...ezviz.Field) {
+	withFields(l.Zerolog.Debug(), fields).Msg(msg)
+}
+
+func (l *Logger) Info(msg string, fields ...ezviz.Field) {
+	withFields(l.Zerolog.Info(), fields).Msg(msg)
+}
+
+func (l *Logger) Warn(msg string, fields ...ezviz.Field) {
+	withFields(l.Zerolog.Warn(), fields).Msg(msg)
+}
+
+func (l *Logger) Error(msg string, fields ...ezviz.Field) {
+	withFields(l.Zerolog.Error(), fields).Msg(msg)
+}
+
+func withFields(e *zerolog.Event, fields []ezviz.Field) *zerolog.Event {
+	for _, f := range fields {
+		e = e.Interface(f.Key, f.Value)
+	}
+	return e
+}